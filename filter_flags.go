@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jdbaldry/spansh/pkg/galaxy"
+)
+
+// namedReferencePoints are the systems --near accepts by name instead of
+// raw coordinates. Sol is the origin of the Spansh/Elite Dangerous
+// coordinate system.
+var namedReferencePoints = map[string]galaxy.Coords{
+	"sol": {X: 0, Y: 0, Z: 0},
+}
+
+// parseNear parses a --near flag value of the form "REFERENCE:LY", where
+// REFERENCE is either a known system name (currently just "Sol") or
+// "X,Y,Z" coordinates, and LY is a radius in light years.
+func parseNear(s string) (galaxy.Coords, float64, error) {
+	ref, lyStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return galaxy.Coords{}, 0, fmt.Errorf("--near %q: want REFERENCE:LY", s)
+	}
+
+	ly, err := strconv.ParseFloat(lyStr, 64)
+	if err != nil {
+		return galaxy.Coords{}, 0, fmt.Errorf("--near %q: invalid radius: %w", s, err)
+	}
+
+	if c, ok := namedReferencePoints[strings.ToLower(ref)]; ok {
+		return c, ly, nil
+	}
+
+	parts := strings.Split(ref, ",")
+	if len(parts) != 3 {
+		return galaxy.Coords{}, 0, fmt.Errorf("--near %q: unknown reference point %q, want a known system name or X,Y,Z", s, ref)
+	}
+	var xyz [3]float64
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return galaxy.Coords{}, 0, fmt.Errorf("--near %q: invalid coordinate %q: %w", s, p, err)
+		}
+		xyz[i] = v
+	}
+	return galaxy.Coords{X: xyz[0], Y: xyz[1], Z: xyz[2]}, ly, nil
+}
+
+// buildFilter combines the --near and --sub-type flags into a single
+// Filter, or returns nil if neither was given.
+func buildFilter(near, subType string) (galaxy.Filter, error) {
+	var filters []galaxy.Filter
+
+	if near != "" {
+		center, ly, err := parseNear(near)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, galaxy.WithinRadius(center, ly))
+	}
+
+	if subType != "" {
+		filters = append(filters, galaxy.HasStarSubType(subType))
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil, nil
+	case 1:
+		return filters[0], nil
+	default:
+		return galaxy.And(filters...), nil
+	}
+}
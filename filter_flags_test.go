@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jdbaldry/spansh/pkg/galaxy"
+)
+
+func TestParseNear(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		wantCoords galaxy.Coords
+		wantLY     float64
+		wantErr    bool
+	}{
+		{name: "named reference point", in: "Sol:50", wantCoords: galaxy.Coords{}, wantLY: 50},
+		{name: "named reference point is case-insensitive", in: "SOL:12.5", wantCoords: galaxy.Coords{}, wantLY: 12.5},
+		{name: "raw coordinates", in: "1,2,3:10", wantCoords: galaxy.Coords{X: 1, Y: 2, Z: 3}, wantLY: 10},
+		{name: "raw coordinates with spaces", in: " 1, 2, 3 :10", wantCoords: galaxy.Coords{X: 1, Y: 2, Z: 3}, wantLY: 10},
+		{name: "missing colon", in: "Sol", wantErr: true},
+		{name: "unknown reference point", in: "Alderaan:50", wantErr: true},
+		{name: "wrong number of coordinates", in: "1,2:50", wantErr: true},
+		{name: "non-numeric coordinate", in: "1,x,3:50", wantErr: true},
+		{name: "non-numeric radius", in: "Sol:far", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCoords, gotLY, err := parseNear(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseNear(%q) = nil error, want an error", tt.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNear(%q) = %v", tt.in, err)
+			}
+			if gotCoords != tt.wantCoords {
+				t.Errorf("parseNear(%q) coords = %v, want %v", tt.in, gotCoords, tt.wantCoords)
+			}
+			if gotLY != tt.wantLY {
+				t.Errorf("parseNear(%q) ly = %v, want %v", tt.in, gotLY, tt.wantLY)
+			}
+		})
+	}
+}
+
+func TestBuildFilter(t *testing.T) {
+	sol := galaxy.Body{Name: "Sol", Coords: galaxy.Coords{}}
+	farAway := galaxy.Body{Name: "Far", Coords: galaxy.Coords{X: 1000, Y: 0, Z: 0}}
+	neutronStar := galaxy.Body{Name: "Sol", Stars: []galaxy.Star{{SubType: "Neutron Star"}}}
+
+	t.Run("no filters", func(t *testing.T) {
+		f, err := buildFilter("", "")
+		if err != nil {
+			t.Fatalf("buildFilter(\"\", \"\") = %v", err)
+		}
+		if f != nil {
+			t.Errorf("buildFilter(\"\", \"\") = %v, want nil", f)
+		}
+	})
+
+	t.Run("near only", func(t *testing.T) {
+		f, err := buildFilter("Sol:50", "")
+		if err != nil {
+			t.Fatalf("buildFilter(\"Sol:50\", \"\") = %v", err)
+		}
+		if !f.Match(sol) || f.Match(farAway) {
+			t.Errorf("buildFilter(\"Sol:50\", \"\") did not filter by radius as expected")
+		}
+	})
+
+	t.Run("sub-type only", func(t *testing.T) {
+		f, err := buildFilter("", "Neutron Star")
+		if err != nil {
+			t.Fatalf("buildFilter(\"\", \"Neutron Star\") = %v", err)
+		}
+		if !f.Match(neutronStar) || f.Match(sol) {
+			t.Errorf("buildFilter(\"\", \"Neutron Star\") did not filter by sub-type as expected")
+		}
+	})
+
+	t.Run("near and sub-type combine with And", func(t *testing.T) {
+		f, err := buildFilter("Sol:50", "Neutron Star")
+		if err != nil {
+			t.Fatalf("buildFilter(\"Sol:50\", \"Neutron Star\") = %v", err)
+		}
+		if f.Match(sol) {
+			t.Error("expected Sol (in radius, wrong sub-type) not to match")
+		}
+		if f.Match(farAway) {
+			t.Error("expected Far (out of radius) not to match")
+		}
+		nearNeutronStar := galaxy.Body{Name: "Sol", Coords: galaxy.Coords{}, Stars: neutronStar.Stars}
+		if !f.Match(nearNeutronStar) {
+			t.Error("expected a body matching both near and sub-type to match")
+		}
+	})
+
+	t.Run("invalid near propagates its error", func(t *testing.T) {
+		if _, err := buildFilter("not-valid", ""); err == nil {
+			t.Error("buildFilter(\"not-valid\", \"\") = nil error, want an error")
+		}
+	})
+}
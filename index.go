@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/jdbaldry/spansh/pkg/compression"
+	"github.com/jdbaldry/spansh/pkg/galaxy"
+	"github.com/jdbaldry/spansh/pkg/rtree"
+)
+
+// runIndex implements the "index" subcommand: it streams GALAXY_PATH once,
+// writes every decoded Body as a line of a companion JSONL file, and
+// bulk-loads an R-tree keyed by ID64 over the bodies' coordinates and JSONL
+// byte offsets. Later radius/box queries can then be answered by querying
+// the tree and seeking directly into the JSONL file, without re-streaming
+// the (possibly tens of GB) original dump.
+func runIndex(args []string, logger *log.Logger) {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	outIndex := fs.String("out-index", "galaxy.idx", "path to write the R-tree index to")
+	outJSONL := fs.String("out-jsonl", "galaxy.jsonl", "path to write the companion JSONL body file to")
+	leafSize := fs.Int("leaf-size", 32, "maximum entries per R-tree node")
+	logLevelFlag := fs.String("log-level", "warn", "minimum severity to log: debug, info, warn or error")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		logger.Fatalln("usage: galaxy index [flags] GALAXY_PATH")
+	}
+
+	logLevel, err := parseLogLevel(*logLevelFlag)
+	if err != nil {
+		logger.Fatalf("ERROR: %v\n", err)
+	}
+	if *leafSize <= 0 {
+		logger.Fatalf("ERROR: -leaf-size must be > 0, got %d\n", *leafSize)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		logger.Fatalf("ERROR: could not open file %q: %v\n", fs.Arg(0), err)
+	}
+	defer f.Close()
+
+	zr, err := compression.NewReader(f)
+	if err != nil {
+		logger.Fatalf("ERROR: %v\n", err)
+	}
+	defer zr.Close()
+
+	jsonlFile, err := os.Create(*outJSONL)
+	if err != nil {
+		logger.Fatalf("ERROR: could not create %q: %v\n", *outJSONL, err)
+	}
+	defer jsonlFile.Close()
+	w := bufio.NewWriter(jsonlFile)
+
+	decoder := galaxy.NewDecoder(zr, galaxy.WithErrorHandler(warnAtLevel(logger, logLevel)))
+
+	var entries []rtree.Entry
+	var offset int64
+	ctx := context.Background()
+	for {
+		body, err := decoder.Decode(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Fatalf("ERROR: %v\n", err)
+		}
+
+		line, err := json.Marshal(body)
+		if err != nil {
+			logger.Fatalf("ERROR: could not marshal JSON: %v\n", err)
+		}
+		if _, err := w.Write(line); err != nil {
+			logger.Fatalf("ERROR: could not write %q: %v\n", *outJSONL, err)
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			logger.Fatalf("ERROR: could not write %q: %v\n", *outJSONL, err)
+		}
+
+		point := rtree.Point{X: body.Coords.X, Y: body.Coords.Y, Z: body.Coords.Z}
+		entries = append(entries, rtree.Entry{
+			ID64:   body.ID64,
+			Box:    rtree.Box{Min: point, Max: point},
+			Offset: offset,
+		})
+		offset += int64(len(line)) + 1
+	}
+
+	if err := w.Flush(); err != nil {
+		logger.Fatalf("ERROR: could not flush %q: %v\n", *outJSONL, err)
+	}
+
+	tree := rtree.BuildSTR(entries, *leafSize)
+
+	idxFile, err := os.Create(*outIndex)
+	if err != nil {
+		logger.Fatalf("ERROR: could not create %q: %v\n", *outIndex, err)
+	}
+	defer idxFile.Close()
+	if err := tree.Save(idxFile); err != nil {
+		logger.Fatalf("ERROR: %v\n", err)
+	}
+
+	logger.Printf("indexed %d bodies into %s and %s\n", len(entries), *outIndex, *outJSONL)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// logLevel gates which severities of the decoder's recoverable-error
+// callback get printed; fatal errors are always reported regardless of
+// level.
+type logLevel int
+
+const (
+	logLevelDebug logLevel = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+// parseLogLevel parses the -log-level flag value.
+func parseLogLevel(s string) (logLevel, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return logLevelDebug, nil
+	case "info":
+		return logLevelInfo, nil
+	case "warn", "warning":
+		return logLevelWarn, nil
+	case "error":
+		return logLevelError, nil
+	default:
+		return 0, fmt.Errorf("-log-level %q: want debug, info, warn or error", s)
+	}
+}
+
+// warnAtLevel returns a galaxy.ErrorHandler that logs through logger at
+// WARNING severity, suppressed if level is above logLevelWarn.
+func warnAtLevel(logger *log.Logger, level logLevel) func(error) {
+	return func(err error) {
+		if level > logLevelWarn {
+			return
+		}
+		logger.Printf("WARNING: %v\n", err)
+	}
+}
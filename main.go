@@ -2,15 +2,17 @@ package main
 
 import (
 	"bytes"
-	"compress/gzip"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
+	"runtime"
 	"text/template"
 
-	"github.com/bcicen/jstream"
+	"github.com/jdbaldry/spansh/pkg/compression"
+	"github.com/jdbaldry/spansh/pkg/galaxy"
 )
 
 // usage prints the usage of the command line tool.
@@ -54,160 +56,91 @@ Examples:
 	return buf.String()
 }
 
-type Coords struct {
-	X float64
-	Y float64
-	Z float64
-}
-type Body struct {
-	ID64   int64
-	Name   string
-	Coords Coords
-	Stars  []Star
-}
-
-type Star struct {
-	ID64              int64
-	BodyID            int64
-	Name              string
-	SubType           string
-	DistanceToArrival float64
-}
-
-func decodeStar(logger *log.Logger, val map[string]any) Star {
-	star := Star{}
-
-	if id, ok := val["id64"]; ok {
-		star.ID64 = int64(id.(float64))
-	} else {
-		// logger.Println("WARNING: no id64 found")
-	}
-
-	if bodyID, ok := val["bodyId"]; ok {
-		star.BodyID = int64(bodyID.(float64))
-	} else {
-		// logger.Println("WARNING: no bodyId found")
-	}
+func main() {
+	logger := log.New(os.Stderr, "", log.Lmsgprefix)
 
-	if name, ok := val["name"]; ok {
-		star.Name = name.(string)
-	} else {
-		// logger.Println("WARNING: no name found")
+	if len(os.Args) > 1 && os.Args[1] == "index" {
+		runIndex(os.Args[2:], logger)
+		return
 	}
 
-	if subType, ok := val["subType"]; ok {
-		star.SubType = subType.(string)
-	} else {
-		// logger.Println("WARNING: no subType found")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of bodies to decode concurrently")
+	buffer := flag.Int("buffer", 64, "size of the channels feeding and draining the worker pool")
+	ordered := flag.Bool("ordered", false, "preserve input order in the output, at the cost of buffering results that finish early")
+	near := flag.String("near", "", `only emit bodies within LY light years of a reference point, as "REFERENCE:LY" (e.g. "Sol:50")`)
+	subType := flag.String("sub-type", "", `only emit bodies with a Star of the given SubType (e.g. "Neutron Star")`)
+	index := flag.String("index", "", "path to a prebuilt index (see the index subcommand); answers -near from it instead of streaming GALAXY")
+	jsonl := flag.String("jsonl", "", "path to the companion JSONL file for -index")
+	output := flag.String("output", "json", `output format: "json" or "parquet"`)
+	outPrefix := flag.String("out-prefix", "galaxy", `with -output parquet, prefix for "<prefix>.bodies.parquet" and "<prefix>.stars.parquet"`)
+	rowGroupSize := flag.Int("row-group-size", 1<<20, "with -output parquet, bodies buffered per Parquet row group")
+	logLevelFlag := flag.String("log-level", "warn", "minimum severity to log: debug, info, warn or error")
+	flag.Parse()
+
+	logLevel, err := parseLogLevel(*logLevelFlag)
+	if err != nil {
+		logger.Fatalf("ERROR: %v\n", err)
 	}
-
-	if dta, ok := val["distanceToArrival"]; ok {
-		star.DistanceToArrival = dta.(float64)
-	} else {
-		// logger.Println("WARNING: no distanceToArrival found")
+	if *workers <= 0 {
+		logger.Fatalf("ERROR: -workers must be > 0, got %d\n", *workers)
 	}
-
-	return star
-}
-
-func decodeBody(logger *log.Logger, val map[string]any) Body {
-	body := Body{}
-
-	if id, ok := val["id64"]; ok {
-		body.ID64 = int64(id.(float64))
-	} else {
-		// logger.Println("WARNING: no id64 found")
+	if *buffer <= 0 {
+		logger.Fatalf("ERROR: -buffer must be > 0, got %d\n", *buffer)
 	}
 
-	if name, ok := val["name"]; ok {
-		body.Name = name.(string)
-	} else {
-		// logger.Println("WARNING: no name found")
+	filter, err := buildFilter(*near, *subType)
+	if err != nil {
+		logger.Fatalf("ERROR: %v\n", err)
 	}
 
-	if c, ok := val["coords"].(map[string]any); ok {
-		coords := Coords{}
-		if x, ok := c["x"]; ok {
-			coords.X = x.(float64)
-		}
-		if y, ok := c["y"]; ok {
-			coords.Y = y.(float64)
-		}
-		if z, ok := c["z"]; ok {
-			coords.Z = z.(float64)
+	var encoder sink
+	switch *output {
+	case "json":
+		encoder = json.NewEncoder(os.Stdout)
+	case "parquet":
+		p, err := newParquetSink(*outPrefix, *rowGroupSize)
+		if err != nil {
+			logger.Fatalf("ERROR: %v\n", err)
 		}
-		body.Coords = coords
-	} else {
-		// logger.Println("WARNING: no coords found")
-	}
-
-	if bodyCount, ok := val["bodyCount"].(float64); ok {
-		if bodyCount := int(bodyCount); bodyCount != 0 {
-			body.Stars = make([]Star, bodyCount, bodyCount)
-
-			starCount := 0
-			if bodies, ok := val["bodies"]; ok {
-				if bodies, ok := bodies.([]any); ok {
-					for _, b := range bodies {
-						if b, ok := b.(map[string]any); ok {
-							if typ, ok := b["type"]; ok {
-								if typ, ok := typ.(string); ok {
-									if typ == "Star" {
-										body.Stars[starCount] = decodeStar(logger, b)
-									}
-								}
-							} else {
-								// logger.Println("WARNING: no type found in child body")
-							}
-						} else {
-							// logger.Printf("WARNING: not a map but a %T\n", bodies)
-						}
-					}
-				} else {
-					// logger.Printf("WARNING: not a slice but a %T\n", bodies)
-				}
-			} else {
-				// logger.Println("WARNING: no bodies in object even though bodyCount is non-zero")
+		defer func() {
+			if err := p.Close(); err != nil {
+				logger.Fatalf("ERROR: %v\n", err)
 			}
+		}()
+		encoder = p
+	default:
+		logger.Fatalf("ERROR: -output %q: want \"json\" or \"parquet\"\n", *output)
+	}
 
-			body.Stars = body.Stars[:starCount]
+	if *index != "" {
+		if *near == "" || *jsonl == "" {
+			logger.Fatalln("ERROR: -index requires both -near and -jsonl")
 		}
-	} else {
-		// logger.Println("WARNING: no bodyCount found")
+		center, ly, err := parseNear(*near)
+		if err != nil {
+			logger.Fatalf("ERROR: %v\n", err)
+		}
+		runIndexedQuery(*index, *jsonl, nearBox(center, ly), filter, encoder, logger)
+		return
 	}
 
-	return body
-}
-
-func main() {
-	logger := log.New(os.Stderr, "", log.Lmsgprefix)
-
-	if len(os.Args) != 2 {
-		// logger.Fatalln(usage())
+	if flag.NArg() != 1 {
+		logger.Fatalln(usage())
 	}
 
-	f, err := os.Open(os.Args[1])
+	f, err := os.Open(flag.Arg(0))
 	if err != nil {
-		// logger.Fatalf("ERROR: could not open file %q: %v\n", os.Args[1], err)
+		logger.Fatalf("ERROR: could not open file %q: %v\n", flag.Arg(0), err)
 	}
+	defer f.Close()
 
-	zr, err := gzip.NewReader(f)
+	zr, err := compression.NewReader(f)
 	if err != nil {
-		// logger.Fatalf("ERROR: could not create gzip reader: %v\n", err)
+		logger.Fatalf("ERROR: %v\n", err)
 	}
+	defer zr.Close()
 
-	lr := io.LimitReader(zr, 5*10e6)
-	// Decode values at the first level (inside the array).
-	decoder := jstream.NewDecoder(lr, 1)
-	encoder := json.NewEncoder(os.Stdout)
-	for mv := range decoder.Stream() {
-		switch val := mv.Value.(type) {
-		case map[string]any:
-			body := decodeBody(logger, val)
-			err := encoder.Encode(body)
-			if err != nil {
-				// logger.Printf("ERROR: could not marshal JSON: %v\n", err)
-			}
-		}
-	}
+	decoder := galaxy.NewDecoder(zr, galaxy.WithErrorHandler(warnAtLevel(logger, logLevel)))
+
+	runPipeline(context.Background(), decoder, encoder, logger, *workers, *buffer, *ordered, filter)
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/jdbaldry/spansh/pkg/galaxy"
+	"github.com/jdbaldry/spansh/pkg/parquetrow"
+)
+
+// rowGroupStats are the per-row-group coordinate bounds written to a
+// parquetSink's _metadata sidecar, so query engines can skip row groups
+// that can't satisfy a spatial predicate without reading them.
+type rowGroupStats struct {
+	MinX, MaxX float64
+	MinY, MaxY float64
+	MinZ, MaxZ float64
+}
+
+// parquetSink buffers decoded bodies into fixed-size row groups, writing a
+// BodyRow per system to a bodies Parquet file and the flattened StarRows
+// directly to a stars Parquet file. It pairs naturally with runPipeline's
+// worker pool: workers call Encode concurrently-decoded bodies into, but
+// Encode itself runs on the single merge goroutine, same as the JSON sink.
+type parquetSink struct {
+	bodies   *parquet.Writer
+	stars    *parquet.Writer
+	metaPath string
+
+	rowGroupSize int
+	buf          []parquetrow.BodyRow
+	stats        []rowGroupStats
+}
+
+// newParquetSink creates "<outPrefix>.bodies.parquet", "<outPrefix>.stars.parquet"
+// and, on Close, "<outPrefix>.bodies.parquet._metadata".
+func newParquetSink(outPrefix string, rowGroupSize int) (*parquetSink, error) {
+	bodiesFile, err := os.Create(outPrefix + ".bodies.parquet")
+	if err != nil {
+		return nil, fmt.Errorf("parquet: could not create bodies file: %w", err)
+	}
+	starsFile, err := os.Create(outPrefix + ".stars.parquet")
+	if err != nil {
+		return nil, fmt.Errorf("parquet: could not create stars file: %w", err)
+	}
+
+	return &parquetSink{
+		bodies:       parquet.NewWriter(bodiesFile, parquet.SchemaOf(parquetrow.BodyRow{})),
+		stars:        parquet.NewWriter(starsFile, parquet.SchemaOf(parquetrow.StarRow{})),
+		metaPath:     outPrefix + ".bodies.parquet._metadata",
+		rowGroupSize: rowGroupSize,
+	}, nil
+}
+
+// Encode adapts Write to the sink interface used by runPipeline.
+func (s *parquetSink) Encode(v any) error {
+	body, ok := v.(galaxy.Body)
+	if !ok {
+		return fmt.Errorf("parquet: unexpected type %T", v)
+	}
+	return s.Write(body)
+}
+
+// Write buffers body's BodyRow and writes its StarRows immediately. Once the
+// buffer reaches rowGroupSize bodies it is flushed as one Parquet row group.
+func (s *parquetSink) Write(body galaxy.Body) error {
+	s.buf = append(s.buf, parquetrow.FromBody(body))
+	for _, star := range parquetrow.StarsFromBody(body) {
+		if err := s.stars.Write(star); err != nil {
+			return fmt.Errorf("parquet: could not write star row: %w", err)
+		}
+	}
+	if len(s.buf) >= s.rowGroupSize {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *parquetSink) flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+
+	stats := rowGroupStats{
+		MinX: math.Inf(1), MinY: math.Inf(1), MinZ: math.Inf(1),
+		MaxX: math.Inf(-1), MaxY: math.Inf(-1), MaxZ: math.Inf(-1),
+	}
+	for _, row := range s.buf {
+		if err := s.bodies.Write(row); err != nil {
+			return fmt.Errorf("parquet: could not write body row: %w", err)
+		}
+		x, y, z := parquetrow.ToLightYears(row.X), parquetrow.ToLightYears(row.Y), parquetrow.ToLightYears(row.Z)
+		stats.MinX, stats.MaxX = math.Min(stats.MinX, x), math.Max(stats.MaxX, x)
+		stats.MinY, stats.MaxY = math.Min(stats.MinY, y), math.Max(stats.MaxY, y)
+		stats.MinZ, stats.MaxZ = math.Min(stats.MinZ, z), math.Max(stats.MaxZ, z)
+	}
+	if err := s.bodies.Flush(); err != nil {
+		return fmt.Errorf("parquet: could not flush row group: %w", err)
+	}
+
+	s.stats = append(s.stats, stats)
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// Close flushes any buffered rows, closes both Parquet files, and writes the
+// _metadata sidecar.
+func (s *parquetSink) Close() error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if err := s.bodies.Close(); err != nil {
+		return fmt.Errorf("parquet: could not close bodies file: %w", err)
+	}
+	if err := s.stars.Close(); err != nil {
+		return fmt.Errorf("parquet: could not close stars file: %w", err)
+	}
+
+	f, err := os.Create(s.metaPath)
+	if err != nil {
+		return fmt.Errorf("parquet: could not create %q: %w", s.metaPath, err)
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(s.stats); err != nil {
+		return fmt.Errorf("parquet: could not write %q: %w", s.metaPath, err)
+	}
+	return nil
+}
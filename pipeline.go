@@ -0,0 +1,153 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/jdbaldry/spansh/pkg/galaxy"
+)
+
+// sink accepts decoded bodies for output. *json.Encoder and *parquetSink
+// both satisfy it.
+type sink interface {
+	Encode(v any) error
+}
+
+// job pairs a raw jstream value with its position in the input, so that
+// ordered output can be reconstructed after concurrent decoding.
+type job struct {
+	seq int
+	raw any
+}
+
+// result is a decoded job, still tagged with its input position. skip is set
+// when seq had no body to encode (a decode error, or the body was excluded
+// by a filter), so mergeOrdered can still advance past it instead of waiting
+// forever for a result that will never arrive.
+type result struct {
+	seq  int
+	body galaxy.Body
+	skip bool
+}
+
+// runPipeline fans values out of decoder across workers workers, decodes
+// them concurrently, and feeds the bodies matching filter to encoder one at
+// a time. A nil filter keeps every body. If ordered is true, bodies are
+// emitted in the same order they were read from decoder, at the cost of
+// buffering results that arrive early.
+func runPipeline(ctx context.Context, decoder *galaxy.Decoder, encoder sink, logger *log.Logger, workers, buffer int, ordered bool, filter galaxy.Filter) {
+	jobs := make(chan job, buffer)
+	results := make(chan result, buffer)
+
+	go feed(ctx, decoder, logger, jobs)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				body, err := decoder.DecodeValue(j.raw)
+				if err != nil {
+					logger.Printf("ERROR: %v\n", err)
+					results <- result{seq: j.seq, skip: true}
+					continue
+				}
+				if filter != nil && !filter.Match(body) {
+					results <- result{seq: j.seq, skip: true}
+					continue
+				}
+				results <- result{seq: j.seq, body: body}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	if ordered {
+		mergeOrdered(results, encoder, logger)
+	} else {
+		mergeUnordered(results, encoder, logger)
+	}
+}
+
+// feed reads raw values off decoder one at a time and pushes them onto jobs
+// tagged with a monotonically increasing sequence number, closing jobs once
+// the stream is exhausted.
+func feed(ctx context.Context, decoder *galaxy.Decoder, logger *log.Logger, jobs chan<- job) {
+	defer close(jobs)
+	for seq := 0; ; seq++ {
+		raw, err := decoder.Next(ctx)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+				logger.Printf("ERROR: %v\n", err)
+			}
+			return
+		}
+		jobs <- job{seq: seq, raw: raw}
+	}
+}
+
+func mergeUnordered(results <-chan result, encoder sink, logger *log.Logger) {
+	for r := range results {
+		if r.skip {
+			continue
+		}
+		if err := encoder.Encode(r.body); err != nil {
+			logger.Printf("ERROR: could not marshal JSON: %v\n", err)
+		}
+	}
+}
+
+// resultHeap orders buffered results by sequence number so mergeOrdered can
+// emit them in input order without blocking on a specific worker.
+type resultHeap []result
+
+func (h resultHeap) Len() int           { return len(h) }
+func (h resultHeap) Less(i, j int) bool { return h[i].seq < h[j].seq }
+func (h resultHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x any)        { *h = append(*h, x.(result)) }
+func (h *resultHeap) Pop() any {
+	old := *h
+	n := len(old)
+	last := old[n-1]
+	*h = old[:n-1]
+	return last
+}
+
+// mergeOrdered re-sequences results that may arrive out of order (workers
+// finish independently) and emits them in the order they were read from the
+// input, buffering any that arrive ahead of the next expected sequence
+// number. Skipped sequence numbers (decode errors, filtered-out bodies)
+// still advance next without being encoded, so a gap never stalls the
+// stream waiting for a result that was never going to arrive.
+func mergeOrdered(results <-chan result, encoder sink, logger *log.Logger) {
+	pending := &resultHeap{}
+	next := 0
+	for r := range results {
+		heap.Push(pending, r)
+		for pending.Len() > 0 && (*pending)[0].seq == next {
+			r := heap.Pop(pending).(result)
+			if !r.skip {
+				if err := encoder.Encode(r.body); err != nil {
+					logger.Printf("ERROR: could not marshal JSON: %v\n", err)
+				}
+			}
+			next++
+		}
+	}
+	for pending.Len() > 0 {
+		r := heap.Pop(pending).(result)
+		if !r.skip {
+			if err := encoder.Encode(r.body); err != nil {
+				logger.Printf("ERROR: could not marshal JSON: %v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/jdbaldry/spansh/pkg/galaxy"
+)
+
+// captureSink records the bodies passed to Encode, in the order Encode was
+// called. runPipeline only ever calls Encode from its merge goroutine, so no
+// locking is needed here.
+type captureSink struct {
+	bodies []galaxy.Body
+}
+
+func (s *captureSink) Encode(v any) error {
+	s.bodies = append(s.bodies, v.(galaxy.Body))
+	return nil
+}
+
+func buildSystemsJSON(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 1; i <= n; i++ {
+		if i > 1 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"id64":%d,"name":"System %d","coords":{"x":0,"y":0,"z":0},"bodyCount":0}`, i, i)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+func oddIDFilter() galaxy.Filter {
+	return galaxy.FilterFunc(func(b galaxy.Body) bool { return b.ID64%2 != 0 })
+}
+
+func systemJSON(id64 int64, name string) string {
+	return fmt.Sprintf(`{"id64":%d,"name":%q,"coords":{"x":0,"y":0,"z":0},"bodyCount":0}`, id64, name)
+}
+
+// notifyingSink sends each encoded body to encoded as Encode is called,
+// rather than just accumulating them, so a test can observe that a body was
+// streamed out before the input is exhausted.
+type notifyingSink struct {
+	encoded chan galaxy.Body
+}
+
+func (s *notifyingSink) Encode(v any) error {
+	s.encoded <- v.(galaxy.Body)
+	return nil
+}
+
+// TestRunPipeline_OrderedSkipsFilteredGaps guards against mergeOrdered
+// stalling on a filtered-out sequence number: it feeds a filtered-out body
+// (seq 0) followed by a kept one (seq 1) through a pipe that is then left
+// open, and requires the kept body to be encoded immediately. Before the
+// fix, mergeOrdered never advanced past the missing seq 0 and only emitted
+// buffered results once the stream closed, so this would time out instead.
+func TestRunPipeline_OrderedSkipsFilteredGaps(t *testing.T) {
+	pr, pw := io.Pipe()
+	decoder := galaxy.NewDecoder(pr)
+	encoded := make(chan galaxy.Body, 1)
+	sink := &notifyingSink{encoded: encoded}
+	logger := log.New(io.Discard, "", 0)
+
+	done := make(chan struct{})
+	go func() {
+		runPipeline(context.Background(), decoder, sink, logger, 2, 4, true, oddIDFilter())
+		close(done)
+	}()
+
+	go func() {
+		io.WriteString(pw, "["+systemJSON(2, "Even")+","+systemJSON(1, "Odd"))
+	}()
+
+	select {
+	case b := <-encoded:
+		if b.ID64 != 1 {
+			t.Fatalf("encoded ID64 %d, want 1", b.ID64)
+		}
+	case <-done:
+		t.Fatal("runPipeline returned before the stream was even closed")
+	case <-time.After(2 * time.Second):
+		t.Fatal("mergeOrdered did not encode the kept body while the filtered-out one ahead of it was still missing -- ordered merge stalled on the gap")
+	}
+
+	io.WriteString(pw, "]")
+	pw.Close()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runPipeline did not return after the stream closed")
+	}
+}
+
+// TestRunPipeline_OrderedPreservesOrder checks the ordinary (non-stalling)
+// case: output is fully re-sequenced and filtered bodies are dropped.
+func TestRunPipeline_OrderedPreservesOrder(t *testing.T) {
+	decoder := galaxy.NewDecoder(bytes.NewReader(buildSystemsJSON(50)))
+	sink := &captureSink{}
+	logger := log.New(io.Discard, "", 0)
+
+	runPipeline(context.Background(), decoder, sink, logger, 4, 8, true, oddIDFilter())
+
+	if len(sink.bodies) != 25 {
+		t.Fatalf("got %d bodies, want 25", len(sink.bodies))
+	}
+	for i, b := range sink.bodies {
+		if b.ID64%2 == 0 {
+			t.Fatalf("encoded even ID64 %d, want only odd IDs kept by the filter", b.ID64)
+		}
+		if i > 0 && b.ID64 < sink.bodies[i-1].ID64 {
+			t.Fatalf("ordered output out of sequence: %+v", sink.bodies)
+		}
+	}
+}
+
+func TestRunPipeline_Unordered(t *testing.T) {
+	decoder := galaxy.NewDecoder(bytes.NewReader(buildSystemsJSON(20)))
+	sink := &captureSink{}
+	logger := log.New(io.Discard, "", 0)
+
+	runPipeline(context.Background(), decoder, sink, logger, 4, 8, false, nil)
+
+	if len(sink.bodies) != 20 {
+		t.Fatalf("got %d bodies, want 20", len(sink.bodies))
+	}
+}
@@ -0,0 +1,68 @@
+// Package compression sniffs the codec a Spansh galaxy dump is stored in
+// and wraps it in a plain io.ReadCloser, so callers don't need to know
+// ahead of time whether they've been handed gzip, zstd, xz or raw JSON.
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	xzMagic   = []byte{0xfd, 0x37, 0x7a, 0x58}
+)
+
+// NewReader peeks at the first few bytes of r to identify its codec and
+// returns an io.ReadCloser yielding the decompressed byte stream. Plain
+// JSON input (starting with '[' or '{') is passed through unmodified.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("compression: could not sniff input: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("compression: could not create gzip reader: %w", err)
+		}
+		return zr, nil
+	case bytes.Equal(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("compression: could not create zstd reader: %w", err)
+		}
+		return &zstdReadCloser{zr}, nil
+	case bytes.Equal(magic, xzMagic):
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("compression: could not create xz reader: %w", err)
+		}
+		return io.NopCloser(xr), nil
+	case len(magic) > 0 && (magic[0] == '[' || magic[0] == '{'):
+		return io.NopCloser(br), nil
+	default:
+		return nil, fmt.Errorf("compression: unrecognized input format (magic bytes %x)", magic)
+	}
+}
+
+// zstdReadCloser adapts *zstd.Decoder, whose Close takes no error, to
+// io.Closer.
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (z *zstdReadCloser) Close() error {
+	z.Decoder.Close()
+	return nil
+}
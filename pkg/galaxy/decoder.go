@@ -0,0 +1,228 @@
+package galaxy
+
+import (
+	"context"
+	"io"
+
+	"github.com/bcicen/jstream"
+)
+
+// ErrorHandler is called for recoverable decode errors, such as a body
+// missing an expected field. It is never called for fatal errors, which are
+// returned directly from Decode.
+type ErrorHandler func(error)
+
+// Option configures a Decoder.
+type Option func(*Decoder)
+
+// WithErrorHandler sets the callback used to report recoverable decode
+// errors. The default ErrorHandler discards them.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(d *Decoder) { d.onError = h }
+}
+
+// Decoder reads a stream of Body values from a Spansh galaxy dump.
+type Decoder struct {
+	jd      *jstream.Decoder
+	onError ErrorHandler
+	values  <-chan *jstream.MetaValue
+}
+
+// NewDecoder returns a Decoder that reads the top-level array elements of r
+// as Body values.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	d := &Decoder{
+		onError: func(error) {},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	// Decode values at the first level (inside the array).
+	d.jd = jstream.NewDecoder(r, 1)
+	d.values = d.jd.Stream()
+	return d
+}
+
+// Decode returns the next Body in the stream. It returns io.EOF once the
+// stream is exhausted, or ctx.Err() if ctx is cancelled first.
+func (d *Decoder) Decode(ctx context.Context) (Body, error) {
+	val, err := d.Next(ctx)
+	if err != nil {
+		return Body{}, err
+	}
+	return d.DecodeValue(val)
+}
+
+// Next returns the next raw JSON value from the stream, as decoded by
+// jstream. It returns io.EOF once the stream is exhausted, or ctx.Err() if
+// ctx is cancelled first. Next is safe to call from a single feeder
+// goroutine that fans values out to concurrent DecodeValue callers; it is
+// not itself safe for concurrent use.
+func (d *Decoder) Next(ctx context.Context) (any, error) {
+	select {
+	case mv, ok := <-d.values:
+		if !ok {
+			return nil, io.EOF
+		}
+		return mv.Value, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// DecodeValue decodes a raw value previously returned by Next into a Body.
+// It is safe to call concurrently from multiple goroutines, which lets
+// callers fan out decoding of independent bodies across a worker pool. A
+// missing or wrong-typed field within an otherwise well-formed body is
+// reported through the ErrorHandler and decoding continues with a partial
+// Body; only when raw isn't a JSON object at all does DecodeValue return a
+// non-nil error, since there is no body to decode.
+func (d *Decoder) DecodeValue(raw any) (Body, error) {
+	val, ok := raw.(map[string]any)
+	if !ok {
+		return Body{}, newFieldError("", "", "body", raw)
+	}
+	return d.decodeBody(val), nil
+}
+
+func (d *Decoder) decodeBody(val map[string]any) Body {
+	body := Body{}
+
+	if id, ok := val["id64"]; ok {
+		if id, ok := id.(float64); ok {
+			body.ID64 = int64(id)
+		} else {
+			d.onError(newFieldError("system", "id64", "float64", id))
+		}
+	} else {
+		d.onError(newMissingFieldError("system", "id64"))
+	}
+
+	if name, ok := val["name"]; ok {
+		if name, ok := name.(string); ok {
+			body.Name = name
+		} else {
+			d.onError(newFieldError("system", "name", "string", name))
+		}
+	} else {
+		d.onError(newMissingFieldError("system", "name"))
+	}
+
+	if c, ok := val["coords"]; ok {
+		if c, ok := c.(map[string]any); ok {
+			body.Coords = decodeCoords(d, "system", c)
+		} else {
+			d.onError(newFieldError("system", "coords", "map[string]any", c))
+		}
+	} else {
+		d.onError(newMissingFieldError("system", "coords"))
+	}
+
+	bodyCount, ok := val["bodyCount"].(float64)
+	if !ok {
+		d.onError(newMissingFieldError("system", "bodyCount"))
+		return body
+	}
+	body.BodyCount = int(bodyCount)
+	if body.BodyCount == 0 {
+		return body
+	}
+
+	bodies, ok := val["bodies"].([]any)
+	if !ok {
+		d.onError(newMissingFieldError("system", "bodies"))
+		return body
+	}
+
+	for _, b := range bodies {
+		child, ok := b.(map[string]any)
+		if !ok {
+			d.onError(newFieldError("child body", "", "map[string]any", b))
+			continue
+		}
+		typ, _ := child["type"].(string)
+		switch typ {
+		case "Star":
+			body.Stars = append(body.Stars, d.decodeStar(child))
+		case "Planet":
+			body.Planets = append(body.Planets, d.decodePlanet(child))
+		case "Belt Cluster":
+			body.BeltClusters = append(body.BeltClusters, d.decodeBeltCluster(child))
+		case "Null":
+			body.Barycentres = append(body.Barycentres, d.decodeBarycentre(child))
+		default:
+			d.onError(newFieldError("child body", "type", `"Star", "Planet", "Belt Cluster" or "Null"`, typ))
+		}
+	}
+
+	return body
+}
+
+func (d *Decoder) decodeStar(val map[string]any) Star {
+	star := Star{}
+	star.ID64 = fieldInt64(d, "star", "id64", val)
+	star.BodyID = fieldInt64(d, "star", "bodyId", val)
+	star.Name = fieldString(d, "star", "name", val)
+	star.SubType = fieldString(d, "star", "subType", val)
+	star.DistanceToArrival = fieldFloat64(d, "star", "distanceToArrival", val)
+	return star
+}
+
+func (d *Decoder) decodePlanet(val map[string]any) Planet {
+	planet := Planet{}
+	planet.ID64 = fieldInt64(d, "planet", "id64", val)
+	planet.BodyID = fieldInt64(d, "planet", "bodyId", val)
+	planet.Name = fieldString(d, "planet", "name", val)
+	planet.SubType = fieldString(d, "planet", "subType", val)
+	planet.DistanceToArrival = fieldFloat64(d, "planet", "distanceToArrival", val)
+	if landable, ok := val["isLandable"]; ok {
+		if landable, ok := landable.(bool); ok {
+			planet.IsLandable = landable
+		} else {
+			d.onError(newFieldError("planet", "isLandable", "bool", landable))
+		}
+	}
+	return planet
+}
+
+func (d *Decoder) decodeBeltCluster(val map[string]any) BeltCluster {
+	belt := BeltCluster{}
+	belt.BodyID = fieldInt64(d, "belt cluster", "bodyId", val)
+	belt.Name = fieldString(d, "belt cluster", "name", val)
+	belt.DistanceToArrival = fieldFloat64(d, "belt cluster", "distanceToArrival", val)
+	return belt
+}
+
+func (d *Decoder) decodeBarycentre(val map[string]any) Barycentre {
+	bary := Barycentre{}
+	bary.BodyID = fieldInt64(d, "barycentre", "bodyId", val)
+	bary.Name = fieldString(d, "barycentre", "name", val)
+	bary.DistanceToArrival = fieldFloat64(d, "barycentre", "distanceToArrival", val)
+	return bary
+}
+
+func decodeCoords(d *Decoder, context string, val map[string]any) Coords {
+	coords := Coords{}
+	if x, ok := val["x"]; ok {
+		if x, ok := x.(float64); ok {
+			coords.X = x
+		} else {
+			d.onError(newFieldError(context, "coords.x", "float64", x))
+		}
+	}
+	if y, ok := val["y"]; ok {
+		if y, ok := y.(float64); ok {
+			coords.Y = y
+		} else {
+			d.onError(newFieldError(context, "coords.y", "float64", y))
+		}
+	}
+	if z, ok := val["z"]; ok {
+		if z, ok := z.(float64); ok {
+			coords.Z = z
+		} else {
+			d.onError(newFieldError(context, "coords.z", "float64", z))
+		}
+	}
+	return coords
+}
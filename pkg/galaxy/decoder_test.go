@@ -0,0 +1,253 @@
+package galaxy
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func newTestDecoder() (*Decoder, *[]error) {
+	var errs []error
+	d := &Decoder{onError: func(err error) { errs = append(errs, err) }}
+	return d, &errs
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		val      map[string]any
+		want     Body
+		wantErrs int
+	}{
+		{
+			name: "all fields present",
+			val: map[string]any{
+				"id64":      float64(1),
+				"name":      "Sol",
+				"coords":    map[string]any{"x": 1.0, "y": 2.0, "z": 3.0},
+				"bodyCount": float64(1),
+				"bodies": []any{
+					map[string]any{"type": "Star", "id64": float64(1), "bodyId": float64(0), "name": "Sol", "subType": "G", "distanceToArrival": 0.0},
+				},
+			},
+			want: Body{
+				ID64:      1,
+				Name:      "Sol",
+				Coords:    Coords{X: 1, Y: 2, Z: 3},
+				BodyCount: 1,
+				Stars:     []Star{{ID64: 1, BodyID: 0, Name: "Sol", SubType: "G"}},
+			},
+		},
+		{
+			// id64, name, coords and bodyCount are all reported missing.
+			name:     "missing fields",
+			val:      map[string]any{},
+			want:     Body{},
+			wantErrs: 4,
+		},
+		{
+			// id64, name and coords are the wrong type; bodyCount is
+			// additionally missing, since decodeBody gives up on bodies
+			// once bodyCount can't be read.
+			name: "wrong types",
+			val: map[string]any{
+				"id64":   "not-a-number",
+				"name":   42,
+				"coords": "not-a-map",
+			},
+			want:     Body{},
+			wantErrs: 4,
+		},
+		{
+			name: "empty bodies list",
+			val: map[string]any{
+				"id64":      float64(1),
+				"name":      "Empty",
+				"coords":    map[string]any{"x": 0.0, "y": 0.0, "z": 0.0},
+				"bodyCount": float64(0),
+			},
+			want: Body{ID64: 1, Name: "Empty", Coords: Coords{}},
+		},
+		{
+			name: "non-Star child body types",
+			val: map[string]any{
+				"id64":      float64(1),
+				"name":      "Mixed",
+				"coords":    map[string]any{"x": 0.0, "y": 0.0, "z": 0.0},
+				"bodyCount": float64(2),
+				"bodies": []any{
+					map[string]any{"type": "Planet", "id64": float64(2), "bodyId": float64(1), "name": "Mercury", "subType": "Rocky body", "distanceToArrival": 10.0, "isLandable": true},
+					map[string]any{"type": "Null", "bodyId": float64(2), "name": "Mixed AB", "distanceToArrival": 5.0},
+				},
+			},
+			want: Body{
+				ID64:      1,
+				Name:      "Mixed",
+				Coords:    Coords{},
+				BodyCount: 2,
+				Planets:   []Planet{{ID64: 2, BodyID: 1, Name: "Mercury", SubType: "Rocky body", DistanceToArrival: 10, IsLandable: true}},
+				Barycentres: []Barycentre{
+					{BodyID: 2, Name: "Mixed AB", DistanceToArrival: 5},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, errs := newTestDecoder()
+			got := d.decodeBody(tt.val)
+			if !bodiesEqual(got, tt.want) {
+				t.Errorf("decodeBody(%v) = %+v, want %+v", tt.val, got, tt.want)
+			}
+			if tt.wantErrs != 0 && len(*errs) != tt.wantErrs {
+				t.Errorf("decodeBody(%v) reported %d errors, want %d: %v", tt.val, len(*errs), tt.wantErrs, *errs)
+			}
+		})
+	}
+}
+
+func TestDecodeStar(t *testing.T) {
+	tests := []struct {
+		name     string
+		val      map[string]any
+		want     Star
+		wantErrs int
+	}{
+		{
+			name: "all fields present",
+			val: map[string]any{
+				"id64":              float64(1),
+				"bodyId":            float64(0),
+				"name":              "Sol",
+				"subType":           "G (White-Yellow) Star",
+				"distanceToArrival": 0.0,
+			},
+			want: Star{ID64: 1, BodyID: 0, Name: "Sol", SubType: "G (White-Yellow) Star"},
+		},
+		{
+			name:     "missing fields",
+			val:      map[string]any{},
+			want:     Star{},
+			wantErrs: 5,
+		},
+		{
+			name: "wrong types",
+			val: map[string]any{
+				"id64":              "not-a-number",
+				"bodyId":            "not-a-number",
+				"name":              42,
+				"subType":           42,
+				"distanceToArrival": "not-a-number",
+			},
+			want:     Star{},
+			wantErrs: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, errs := newTestDecoder()
+			got := d.decodeStar(tt.val)
+			if got != tt.want {
+				t.Errorf("decodeStar(%v) = %+v, want %+v", tt.val, got, tt.want)
+			}
+			if len(*errs) != tt.wantErrs {
+				t.Errorf("decodeStar(%v) reported %d errors, want %d: %v", tt.val, len(*errs), tt.wantErrs, *errs)
+			}
+		})
+	}
+}
+
+func bodiesEqual(a, b Body) bool {
+	if a.ID64 != b.ID64 || a.Name != b.Name || a.Coords != b.Coords || a.BodyCount != b.BodyCount {
+		return false
+	}
+	if len(a.Stars) != len(b.Stars) || len(a.Planets) != len(b.Planets) ||
+		len(a.BeltClusters) != len(b.BeltClusters) || len(a.Barycentres) != len(b.Barycentres) {
+		return false
+	}
+	for i := range a.Stars {
+		if a.Stars[i] != b.Stars[i] {
+			return false
+		}
+	}
+	for i := range a.Planets {
+		if a.Planets[i] != b.Planets[i] {
+			return false
+		}
+	}
+	for i := range a.BeltClusters {
+		if a.BeltClusters[i] != b.BeltClusters[i] {
+			return false
+		}
+	}
+	for i := range a.Barycentres {
+		if a.Barycentres[i] != b.Barycentres[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestDecoder_Fixture decodes testdata/galaxy.json.gz end to end, exercising
+// NewDecoder and Decode together with gzip the way the CLI's compression
+// package does, rather than decodeBody/decodeStar in isolation.
+func TestDecoder_Fixture(t *testing.T) {
+	f, err := os.Open("testdata/galaxy.json.gz")
+	if err != nil {
+		t.Fatalf("could not open fixture: %v", err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("could not create gzip reader: %v", err)
+	}
+	defer zr.Close()
+
+	var warnings []error
+	d := NewDecoder(zr, WithErrorHandler(func(err error) { warnings = append(warnings, err) }))
+
+	var bodies []Body
+	ctx := context.Background()
+	for {
+		body, err := d.Decode(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		bodies = append(bodies, body)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("got %d bodies, want 2", len(bodies))
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings decoding fixture: %v", warnings)
+	}
+
+	sol := bodies[0]
+	if sol.Name != "Sol" {
+		t.Errorf("bodies[0].Name = %q, want \"Sol\"", sol.Name)
+	}
+	if len(sol.Stars) != 1 || sol.Stars[0].Name != "Sol" {
+		t.Errorf("bodies[0].Stars = %+v, want one star named Sol", sol.Stars)
+	}
+	if len(sol.Planets) != 1 || sol.Planets[0].Name != "Mercury" {
+		t.Errorf("bodies[0].Planets = %+v, want one planet named Mercury", sol.Planets)
+	}
+	if len(sol.BeltClusters) != 1 {
+		t.Errorf("bodies[0].BeltClusters = %+v, want one belt cluster", sol.BeltClusters)
+	}
+
+	alphaCen := bodies[1]
+	if alphaCen.Name != "Alpha Centauri" || alphaCen.BodyCount != 0 {
+		t.Errorf("bodies[1] = %+v, want empty Alpha Centauri system", alphaCen)
+	}
+}
@@ -0,0 +1,69 @@
+package galaxy
+
+import "fmt"
+
+// fieldError describes a field that was missing or had an unexpected type
+// while decoding a body of the given kind (e.g. "star", "planet").
+type fieldError struct {
+	kind  string
+	field string
+	want  string
+	got   any
+}
+
+func (e *fieldError) Error() string {
+	if e.got == nil {
+		return fmt.Sprintf("%s: missing field %q", e.kind, e.field)
+	}
+	return fmt.Sprintf("%s: field %q: want %s, got %T", e.kind, e.field, e.want, e.got)
+}
+
+func newFieldError(kind, field, want string, got any) error {
+	return &fieldError{kind: kind, field: field, want: want, got: got}
+}
+
+func newMissingFieldError(kind, field string) error {
+	return &fieldError{kind: kind, field: field}
+}
+
+func fieldInt64(d *Decoder, kind, field string, val map[string]any) int64 {
+	v, ok := val[field]
+	if !ok {
+		d.onError(newMissingFieldError(kind, field))
+		return 0
+	}
+	f, ok := v.(float64)
+	if !ok {
+		d.onError(newFieldError(kind, field, "float64", v))
+		return 0
+	}
+	return int64(f)
+}
+
+func fieldFloat64(d *Decoder, kind, field string, val map[string]any) float64 {
+	v, ok := val[field]
+	if !ok {
+		d.onError(newMissingFieldError(kind, field))
+		return 0
+	}
+	f, ok := v.(float64)
+	if !ok {
+		d.onError(newFieldError(kind, field, "float64", v))
+		return 0
+	}
+	return f
+}
+
+func fieldString(d *Decoder, kind, field string, val map[string]any) string {
+	v, ok := val[field]
+	if !ok {
+		d.onError(newMissingFieldError(kind, field))
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		d.onError(newFieldError(kind, field, "string", v))
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package galaxy
+
+import (
+	"math"
+	"path/filepath"
+)
+
+// Filter reports whether a Body should be kept.
+type Filter interface {
+	Match(Body) bool
+}
+
+// FilterFunc adapts a function to a Filter.
+type FilterFunc func(Body) bool
+
+// Match implements Filter.
+func (f FilterFunc) Match(b Body) bool { return f(b) }
+
+// WithinRadius matches bodies within ly light years of center.
+func WithinRadius(center Coords, ly float64) Filter {
+	return FilterFunc(func(b Body) bool {
+		return distance(center, b.Coords) <= ly
+	})
+}
+
+// WithinBox matches bodies whose coordinates fall within the axis-aligned
+// box bounded by min and max, inclusive.
+func WithinBox(min, max Coords) Filter {
+	return FilterFunc(func(b Body) bool {
+		c := b.Coords
+		return c.X >= min.X && c.X <= max.X &&
+			c.Y >= min.Y && c.Y <= max.Y &&
+			c.Z >= min.Z && c.Z <= max.Z
+	})
+}
+
+// NameGlob matches bodies whose Name matches the shell glob pattern, as
+// interpreted by path/filepath.Match.
+func NameGlob(pattern string) Filter {
+	return FilterFunc(func(b Body) bool {
+		ok, err := filepath.Match(pattern, b.Name)
+		return err == nil && ok
+	})
+}
+
+// HasStarSubType matches bodies with at least one Star of the given
+// SubType, e.g. "Neutron Star".
+func HasStarSubType(subType string) Filter {
+	return FilterFunc(func(b Body) bool {
+		for _, s := range b.Stars {
+			if s.SubType == subType {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// And matches bodies that match every one of filters.
+func And(filters ...Filter) Filter {
+	return FilterFunc(func(b Body) bool {
+		for _, f := range filters {
+			if !f.Match(b) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or matches bodies that match at least one of filters.
+func Or(filters ...Filter) Filter {
+	return FilterFunc(func(b Body) bool {
+		for _, f := range filters {
+			if f.Match(b) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not matches bodies that do not match f.
+func Not(f Filter) Filter {
+	return FilterFunc(func(b Body) bool { return !f.Match(b) })
+}
+
+func distance(a, b Coords) float64 {
+	dx, dy, dz := a.X-b.X, a.Y-b.Y, a.Z-b.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
@@ -0,0 +1,127 @@
+package galaxy
+
+import "testing"
+
+func bodyAt(name string, x, y, z float64) Body {
+	return Body{Name: name, Coords: Coords{X: x, Y: y, Z: z}}
+}
+
+func TestWithinRadius(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Body
+		ly   float64
+		want bool
+	}{
+		{name: "at center", b: bodyAt("Sol", 0, 0, 0), ly: 0, want: true},
+		{name: "inside radius", b: bodyAt("Nearby", 1, 0, 0), ly: 2, want: true},
+		{name: "exactly on radius", b: bodyAt("Edge", 3, 4, 0), ly: 5, want: true},
+		{name: "outside radius", b: bodyAt("Far", 10, 0, 0), ly: 5, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithinRadius(Coords{}, tt.ly).Match(tt.b); got != tt.want {
+				t.Errorf("WithinRadius(origin, %v).Match(%v) = %v, want %v", tt.ly, tt.b.Coords, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinBox(t *testing.T) {
+	min, max := Coords{X: 0, Y: 0, Z: 0}, Coords{X: 10, Y: 10, Z: 10}
+	tests := []struct {
+		name string
+		b    Body
+		want bool
+	}{
+		{name: "inside", b: bodyAt("Inside", 5, 5, 5), want: true},
+		{name: "on min boundary", b: bodyAt("Min", 0, 0, 0), want: true},
+		{name: "on max boundary", b: bodyAt("Max", 10, 10, 10), want: true},
+		{name: "just outside min", b: bodyAt("Outside", -0.001, 5, 5), want: false},
+		{name: "just outside max", b: bodyAt("Outside", 5, 5, 10.001), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WithinBox(min, max).Match(tt.b); got != tt.want {
+				t.Errorf("WithinBox(%v, %v).Match(%v) = %v, want %v", min, max, tt.b.Coords, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameGlob(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		body    Body
+		want    bool
+	}{
+		{name: "exact match", pattern: "Sol", body: Body{Name: "Sol"}, want: true},
+		{name: "wildcard match", pattern: "Sol*", body: Body{Name: "Sol 2"}, want: true},
+		{name: "no match", pattern: "Sol*", body: Body{Name: "Alpha Centauri"}, want: false},
+		{name: "malformed pattern", pattern: "[", body: Body{Name: "Sol"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NameGlob(tt.pattern).Match(tt.body); got != tt.want {
+				t.Errorf("NameGlob(%q).Match(%q) = %v, want %v", tt.pattern, tt.body.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasStarSubType(t *testing.T) {
+	body := Body{Stars: []Star{{SubType: "G (White-Yellow) Star"}, {SubType: "Neutron Star"}}}
+
+	if !HasStarSubType("Neutron Star").Match(body) {
+		t.Error("HasStarSubType(\"Neutron Star\").Match(body) = false, want true")
+	}
+	if HasStarSubType("White Dwarf").Match(body) {
+		t.Error("HasStarSubType(\"White Dwarf\").Match(body) = true, want false")
+	}
+	if HasStarSubType("Neutron Star").Match(Body{}) {
+		t.Error("HasStarSubType(\"Neutron Star\").Match(Body{}) = true, want false")
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	isSol := FilterFunc(func(b Body) bool { return b.Name == "Sol" })
+	isAlpha := FilterFunc(func(b Body) bool { return b.Name == "Alpha Centauri" })
+	sol, alpha, other := Body{Name: "Sol"}, Body{Name: "Alpha Centauri"}, Body{Name: "Proxima Centauri"}
+
+	t.Run("And", func(t *testing.T) {
+		and := And(isSol, Not(isAlpha))
+		if !and.Match(sol) {
+			t.Error("And(isSol, Not(isAlpha)).Match(sol) = false, want true")
+		}
+		if and.Match(alpha) {
+			t.Error("And(isSol, Not(isAlpha)).Match(alpha) = true, want false")
+		}
+		if And().Match(other) != true {
+			t.Error("And() with no filters should match everything")
+		}
+	})
+
+	t.Run("Or", func(t *testing.T) {
+		or := Or(isSol, isAlpha)
+		if !or.Match(sol) || !or.Match(alpha) {
+			t.Error("Or(isSol, isAlpha) did not match sol or alpha")
+		}
+		if or.Match(other) {
+			t.Error("Or(isSol, isAlpha).Match(other) = true, want false")
+		}
+		if Or().Match(other) != false {
+			t.Error("Or() with no filters should match nothing")
+		}
+	})
+
+	t.Run("Not", func(t *testing.T) {
+		not := Not(isSol)
+		if not.Match(sol) {
+			t.Error("Not(isSol).Match(sol) = true, want false")
+		}
+		if !not.Match(alpha) {
+			t.Error("Not(isSol).Match(alpha) = false, want true")
+		}
+	})
+}
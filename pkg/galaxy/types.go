@@ -0,0 +1,56 @@
+// Package galaxy decodes Spansh galaxy dump JSON into typed Go values.
+package galaxy
+
+// Coords is a 3D position in light years relative to Sol.
+type Coords struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// Body is a star system as it appears in a Spansh galaxy dump: a top-level
+// object keyed by ID64 with zero or more child bodies grouped by type.
+type Body struct {
+	ID64         int64
+	Name         string
+	Coords       Coords
+	BodyCount    int
+	Stars        []Star
+	Planets      []Planet
+	BeltClusters []BeltCluster
+	Barycentres  []Barycentre
+}
+
+// Star is a child body with type "Star".
+type Star struct {
+	ID64              int64
+	BodyID            int64
+	Name              string
+	SubType           string
+	DistanceToArrival float64
+}
+
+// Planet is a child body with type "Planet".
+type Planet struct {
+	ID64              int64
+	BodyID            int64
+	Name              string
+	SubType           string
+	DistanceToArrival float64
+	IsLandable        bool
+}
+
+// BeltCluster is a child body with type "Belt Cluster".
+type BeltCluster struct {
+	BodyID            int64
+	Name              string
+	DistanceToArrival float64
+}
+
+// Barycentre is a child body with type "Null" that marks the centre of mass
+// of an orbiting pair rather than a physical body.
+type Barycentre struct {
+	BodyID            int64
+	Name              string
+	DistanceToArrival float64
+}
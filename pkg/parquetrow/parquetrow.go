@@ -0,0 +1,67 @@
+// Package parquetrow flattens galaxy.Body values into the row types used
+// for Parquet output: one row per system, and one row per star.
+package parquetrow
+
+import "github.com/jdbaldry/spansh/pkg/galaxy"
+
+// coordScale converts light-year float64 coordinates to the fixed-point
+// int64 microlight-years that BodyRow.X/Y/Z store, since Parquet's delta
+// encoding only applies to integer columns.
+const coordScale = 1e6
+
+// BodyRow is one system, with its coordinates split into separate
+// delta-encoded microlight-year columns and its Name dictionary-encoded,
+// since the same system names recur across the galaxy (e.g. numbered
+// belt/planet designations sharing a root).
+type BodyRow struct {
+	ID64      int64  `parquet:"id64"`
+	Name      string `parquet:"name,dict"`
+	X         int64  `parquet:"x,delta"`
+	Y         int64  `parquet:"y,delta"`
+	Z         int64  `parquet:"z,delta"`
+	BodyCount int32  `parquet:"body_count"`
+}
+
+// StarRow is one star, flattened out of its parent system's Stars slice and
+// tagged with SystemID64 so it can be joined back to its BodyRow.
+type StarRow struct {
+	SystemID64        int64   `parquet:"system_id64"`
+	ID64              int64   `parquet:"id64"`
+	BodyID            int64   `parquet:"body_id"`
+	Name              string  `parquet:"name,dict"`
+	SubType           string  `parquet:"sub_type,dict"`
+	DistanceToArrival float64 `parquet:"distance_to_arrival"`
+}
+
+// FromBody converts a Body into its BodyRow.
+func FromBody(b galaxy.Body) BodyRow {
+	return BodyRow{
+		ID64:      b.ID64,
+		Name:      b.Name,
+		X:         int64(b.Coords.X * coordScale),
+		Y:         int64(b.Coords.Y * coordScale),
+		Z:         int64(b.Coords.Z * coordScale),
+		BodyCount: int32(b.BodyCount),
+	}
+}
+
+// ToLightYears converts a BodyRow coordinate back to light years.
+func ToLightYears(fixedPoint int64) float64 {
+	return float64(fixedPoint) / coordScale
+}
+
+// StarsFromBody flattens b.Stars into StarRows tagged with b's ID64.
+func StarsFromBody(b galaxy.Body) []StarRow {
+	rows := make([]StarRow, len(b.Stars))
+	for i, s := range b.Stars {
+		rows[i] = StarRow{
+			SystemID64:        b.ID64,
+			ID64:              s.ID64,
+			BodyID:            s.BodyID,
+			Name:              s.Name,
+			SubType:           s.SubType,
+			DistanceToArrival: s.DistanceToArrival,
+		}
+	}
+	return rows
+}
@@ -0,0 +1,224 @@
+// Package rtree is a bulk-loaded, read-only R-tree over 3D bounding boxes,
+// used to answer spatial queries against a galaxy dump without re-streaming
+// it. Entries are keyed by ID64 and carry a byte offset into a companion
+// JSONL file holding the decoded Body for that entry.
+package rtree
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Point is a 3D coordinate.
+type Point struct {
+	X, Y, Z float64
+}
+
+// Box is an axis-aligned bounding box.
+type Box struct {
+	Min, Max Point
+}
+
+// Entry is a single indexed body.
+type Entry struct {
+	ID64   int64
+	Box    Box
+	Offset int64
+}
+
+func boxOf(p Point) Box { return Box{Min: p, Max: p} }
+
+func union(a, b Box) Box {
+	return Box{
+		Min: Point{min(a.Min.X, b.Min.X), min(a.Min.Y, b.Min.Y), min(a.Min.Z, b.Min.Z)},
+		Max: Point{max(a.Max.X, b.Max.X), max(a.Max.Y, b.Max.Y), max(a.Max.Z, b.Max.Z)},
+	}
+}
+
+func intersects(a, b Box) bool {
+	return a.Min.X <= b.Max.X && a.Max.X >= b.Min.X &&
+		a.Min.Y <= b.Max.Y && a.Max.Y >= b.Min.Y &&
+		a.Min.Z <= b.Max.Z && a.Max.Z >= b.Min.Z
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// node is either a leaf, holding entries directly, or an internal node
+// holding children. Exactly one of entries/children is non-nil.
+type node struct {
+	Box      Box
+	Entries  []Entry
+	Children []*node
+}
+
+func (n *node) isLeaf() bool { return n.Children == nil }
+
+// Tree is a bulk-loaded R-tree. The zero value is not usable; build one
+// with BuildSTR or read one with Read.
+type Tree struct {
+	root *node
+}
+
+// BuildSTR bulk-loads a Tree from entries using the Sort-Tile-Recursive
+// algorithm: entries are sorted by X and sliced into ceil(sqrt(N/m))
+// vertical slabs, each slab is sorted by Y and sliced into leaves of m
+// entries, and the resulting leaves are packed into internal nodes the same
+// way, recursively, until a single root remains.
+func BuildSTR(entries []Entry, m int) *Tree {
+	if len(entries) == 0 {
+		return &Tree{root: &node{Entries: []Entry{}}}
+	}
+
+	leaves := strSlabs(entries, m, func(e Entry) Box { return e.Box })
+	level := make([]*node, len(leaves))
+	for i, leafEntries := range leaves {
+		es := make([]Entry, len(leafEntries))
+		copy(es, leafEntries)
+		level[i] = &node{Box: boxesOf(es), Entries: es}
+	}
+
+	for len(level) > 1 {
+		groups := strSlabs(level, m, func(n *node) Box { return n.Box })
+		next := make([]*node, len(groups))
+		for i, group := range groups {
+			children := make([]*node, len(group))
+			copy(children, group)
+			next[i] = &node{Box: childBoxesOf(children), Children: children}
+		}
+		level = next
+	}
+
+	return &Tree{root: level[0]}
+}
+
+func boxesOf(entries []Entry) Box {
+	b := entries[0].Box
+	for _, e := range entries[1:] {
+		b = union(b, e.Box)
+	}
+	return b
+}
+
+func childBoxesOf(children []*node) Box {
+	b := children[0].Box
+	for _, c := range children[1:] {
+		b = union(b, c.Box)
+	}
+	return b
+}
+
+// strSlabs partitions items into leaves of at most m entries using the
+// Sort-Tile-Recursive layout: sort by X, split into ceil(sqrt(len/m))
+// vertical slabs, sort each slab by Y, then split each slab into runs of m.
+func strSlabs[T any](items []T, m int, boxOf func(T) Box) [][]T {
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool { return boxOf(sorted[i]).Min.X < boxOf(sorted[j]).Min.X })
+
+	leafCount := (len(sorted) + m - 1) / m
+	slabCount := int(math.Ceil(math.Sqrt(float64(leafCount))))
+	if slabCount < 1 {
+		slabCount = 1
+	}
+	slabSize := (len(sorted) + slabCount - 1) / slabCount
+
+	var leaves [][]T
+	for start := 0; start < len(sorted); start += slabSize {
+		end := start + slabSize
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		slab := sorted[start:end]
+		sort.Slice(slab, func(i, j int) bool { return boxOf(slab[i]).Min.Y < boxOf(slab[j]).Min.Y })
+		for s := 0; s < len(slab); s += m {
+			e := s + m
+			if e > len(slab) {
+				e = len(slab)
+			}
+			leaves = append(leaves, slab[s:e])
+		}
+	}
+	return leaves
+}
+
+// Query returns every Entry whose bounding box intersects box.
+func (t *Tree) Query(box Box) []Entry {
+	var results []Entry
+	var walk func(n *node)
+	walk = func(n *node) {
+		if !intersects(n.Box, box) {
+			return
+		}
+		if n.isLeaf() {
+			for _, e := range n.Entries {
+				if intersects(e.Box, box) {
+					results = append(results, e)
+				}
+			}
+			return
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	if t.root != nil {
+		walk(t.root)
+	}
+	return results
+}
+
+// gobNode is the gob-friendly flattening of node, since node's internal
+// Children are pointers.
+type gobNode struct {
+	Box      Box
+	Entries  []Entry
+	Children []gobNode
+}
+
+func toGob(n *node) gobNode {
+	g := gobNode{Box: n.Box, Entries: n.Entries}
+	for _, c := range n.Children {
+		g.Children = append(g.Children, toGob(c))
+	}
+	return g
+}
+
+func fromGob(g gobNode) *node {
+	n := &node{Box: g.Box, Entries: g.Entries}
+	for _, c := range g.Children {
+		n.Children = append(n.Children, fromGob(c))
+	}
+	return n
+}
+
+// Save gob-encodes the tree to w.
+func (t *Tree) Save(w io.Writer) error {
+	if err := gob.NewEncoder(w).Encode(toGob(t.root)); err != nil {
+		return fmt.Errorf("rtree: could not encode tree: %w", err)
+	}
+	return nil
+}
+
+// Read decodes a Tree previously written with Save.
+func Read(r io.Reader) (*Tree, error) {
+	var g gobNode
+	if err := gob.NewDecoder(r).Decode(&g); err != nil {
+		return nil, fmt.Errorf("rtree: could not decode tree: %w", err)
+	}
+	return &Tree{root: fromGob(g)}, nil
+}
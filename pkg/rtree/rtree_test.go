@@ -0,0 +1,95 @@
+package rtree
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func entryAt(id64 int64, x, y, z float64) Entry {
+	p := Point{X: x, Y: y, Z: z}
+	return Entry{ID64: id64, Box: Box{Min: p, Max: p}, Offset: id64 * 10}
+}
+
+func sortedIDs(entries []Entry) []int64 {
+	ids := make([]int64, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID64
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestBuildSTR_Empty(t *testing.T) {
+	tree := BuildSTR(nil, 4)
+	if got := tree.Query(Box{Min: Point{-1, -1, -1}, Max: Point{1, 1, 1}}); len(got) != 0 {
+		t.Errorf("Query() on an empty tree = %v, want none", got)
+	}
+}
+
+func TestBuildSTR_QueryFindsEntriesWithinBox(t *testing.T) {
+	entries := []Entry{
+		entryAt(1, 0, 0, 0),
+		entryAt(2, 5, 5, 5),
+		entryAt(3, -5, -5, -5),
+		entryAt(4, 100, 100, 100),
+		entryAt(5, 1, 1, 1),
+	}
+	tree := BuildSTR(entries, 2)
+
+	got := tree.Query(Box{Min: Point{-1, -1, -1}, Max: Point{2, 2, 2}})
+	want := []int64{1, 5}
+	if got := sortedIDs(got); !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildSTR_QueryMatchesEveryEntryWhenBoxCoversAll(t *testing.T) {
+	var entries []Entry
+	for i := int64(0); i < 50; i++ {
+		entries = append(entries, entryAt(i, float64(i), float64(-i), float64(i%7)))
+	}
+	tree := BuildSTR(entries, 4)
+
+	got := tree.Query(Box{Min: Point{-1000, -1000, -1000}, Max: Point{1000, 1000, 1000}})
+	if len(got) != len(entries) {
+		t.Fatalf("Query() returned %d entries, want %d", len(got), len(entries))
+	}
+}
+
+func TestBuildSTR_QueryExcludesEntriesOutsideBox(t *testing.T) {
+	entries := []Entry{entryAt(1, 0, 0, 0), entryAt(2, 500, 500, 500)}
+	tree := BuildSTR(entries, 2)
+
+	got := tree.Query(Box{Min: Point{-1, -1, -1}, Max: Point{1, 1, 1}})
+	want := []int64{1}
+	if got := sortedIDs(got); !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %v, want %v", got, want)
+	}
+}
+
+func TestTree_SaveAndRead(t *testing.T) {
+	entries := []Entry{
+		entryAt(1, 0, 0, 0),
+		entryAt(2, 5, 5, 5),
+		entryAt(3, -5, -5, -5),
+	}
+	tree := BuildSTR(entries, 2)
+
+	var buf bytes.Buffer
+	if err := tree.Save(&buf); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() = %v", err)
+	}
+
+	box := Box{Min: Point{-1000, -1000, -1000}, Max: Point{1000, 1000, 1000}}
+	want := sortedIDs(tree.Query(box))
+	if got := sortedIDs(got.Query(box)); !reflect.DeepEqual(got, want) {
+		t.Errorf("round-tripped Query() = %v, want %v", got, want)
+	}
+}
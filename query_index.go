@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+
+	"github.com/jdbaldry/spansh/pkg/galaxy"
+	"github.com/jdbaldry/spansh/pkg/rtree"
+)
+
+// nearBox returns the axis-aligned bounding box that a --near query must
+// intersect, used as a coarse prefilter before the exact radius check.
+func nearBox(center galaxy.Coords, ly float64) rtree.Box {
+	return rtree.Box{
+		Min: rtree.Point{X: center.X - ly, Y: center.Y - ly, Z: center.Z - ly},
+		Max: rtree.Point{X: center.X + ly, Y: center.Y + ly, Z: center.Z + ly},
+	}
+}
+
+// runIndexedQuery answers a query against a prebuilt R-tree index and its
+// companion JSONL file instead of re-streaming the original galaxy dump:
+// it queries the tree for entries whose bounding box intersects box, seeks
+// to each match's offset in jsonlPath, and emits the bodies that also pass
+// filter.
+func runIndexedQuery(indexPath, jsonlPath string, box rtree.Box, filter galaxy.Filter, encoder sink, logger *log.Logger) {
+	idxFile, err := os.Open(indexPath)
+	if err != nil {
+		logger.Fatalf("ERROR: could not open %q: %v\n", indexPath, err)
+	}
+	tree, err := rtree.Read(idxFile)
+	idxFile.Close()
+	if err != nil {
+		logger.Fatalf("ERROR: %v\n", err)
+	}
+
+	jsonlFile, err := os.Open(jsonlPath)
+	if err != nil {
+		logger.Fatalf("ERROR: could not open %q: %v\n", jsonlPath, err)
+	}
+	defer jsonlFile.Close()
+
+	for _, e := range tree.Query(box) {
+		if _, err := jsonlFile.Seek(e.Offset, io.SeekStart); err != nil {
+			logger.Fatalf("ERROR: could not seek %q: %v\n", jsonlPath, err)
+		}
+		line, err := bufio.NewReader(jsonlFile).ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			logger.Fatalf("ERROR: could not read %q: %v\n", jsonlPath, err)
+		}
+
+		var body galaxy.Body
+		if err := json.Unmarshal(line, &body); err != nil {
+			logger.Printf("ERROR: could not unmarshal entry at offset %d: %v\n", e.Offset, err)
+			continue
+		}
+		if filter != nil && !filter.Match(body) {
+			continue
+		}
+		if err := encoder.Encode(body); err != nil {
+			logger.Printf("ERROR: could not marshal JSON: %v\n", err)
+		}
+	}
+}